@@ -67,6 +67,38 @@ var srcFlags = []cli.Flag{
 		Name:  "input-file",
 		Usage: "file with list of entries to copy from DR",
 	},
+	cli.StringFlag{
+		Name:  "src-sse-c-key",
+		Usage: "SSE-C customer key to decrypt source objects",
+	},
+	cli.StringFlag{
+		Name:  "src-sse-c-key-file",
+		Usage: "file containing the SSE-C customer key to decrypt source objects",
+	},
+	cli.StringFlag{
+		Name:  "tgt-sse-c-key",
+		Usage: "SSE-C customer key to encrypt target objects",
+	},
+	cli.StringFlag{
+		Name:  "tgt-sse-kms-key-id",
+		Usage: "SSE-KMS key id to encrypt target objects",
+	},
+	cli.BoolFlag{
+		Name:  "preserve-locks",
+		Usage: "replay object lock retention and legal hold from source onto target",
+	},
+	cli.BoolTFlag{
+		Name:  "preserve-metadata",
+		Usage: "carry over tags, user metadata, ACLs and storage class from source to target (default: true)",
+	},
+	cli.BoolFlag{
+		Name:  "metadata-only",
+		Usage: "re-sync metadata without re-uploading bytes when target ETag already matches source",
+	},
+	cli.BoolFlag{
+		Name:  "server-side-copy",
+		Usage: "use CopyObject/ComposeObject to copy server-side instead of streaming bytes through the tool",
+	},
 }
 
 var copyCmd = cli.Command{
@@ -109,6 +141,13 @@ func checkCopyArgsAndInit(ctx *cli.Context) {
 	tgtBucket = ctx.String("bucket")
 	dirPath = ctx.String("data-dir")
 	versions = ctx.Bool("versions")
+	preserveLocks = ctx.Bool("preserve-locks")
+	preserveMetadata = ctx.BoolT("preserve-metadata")
+	metadataOnly = ctx.Bool("metadata-only")
+	serverSideCopy = ctx.Bool("server-side-copy")
+	if err := encryptFlagsFromCLI(ctx.String("src-sse-c-key"), ctx.String("src-sse-c-key-file"), ctx.String("tgt-sse-c-key"), ctx.String("tgt-sse-kms-key-id")); err != nil {
+		log.Fatalln(err)
+	}
 	if tgtEndpoint == "" {
 		log.Fatalln("--endpoint is not provided for target")
 	}
@@ -203,6 +242,7 @@ func copyAction(cliCtx *cli.Context) error {
 		return fmt.Errorf("could not initialize tgt client %w", err)
 	}
 	ctx := context.Background()
+	logBucketEncryption(ctx, tgtClient, tgtBucket)
 	copyState = newcopyState(ctx)
 	copyState.init(ctx)
 	skip := cliCtx.Int("skip")
@@ -219,9 +259,13 @@ func copyAction(cliCtx *cli.Context) error {
 			skip--
 			continue
 		}
-		slc := strings.SplitN(o, ",", 4)
-		if len(slc) < 3 || len(slc) > 4 {
+		// bucket,object,versionID,deleteMarker are always present; when
+		// --preserve-locks is set the line may additionally carry
+		// retention_mode,retain_until,legal_hold.
+		slc := strings.SplitN(o, ",", 7)
+		if len(slc) < 4 || len(slc) > 7 {
 			logDMsg(fmt.Sprintf("error processing line :%s ", o), nil)
+			continue
 		}
 		obj := objInfo{
 			bucket:       strings.TrimSpace(slc[0]),
@@ -229,6 +273,11 @@ func copyAction(cliCtx *cli.Context) error {
 			versionID:    strings.TrimSpace(slc[2]),
 			deleteMarker: strings.TrimSpace(slc[3]) == "true",
 		}
+		if preserveLocks && len(slc) == 7 {
+			obj.retentionMode = strings.TrimSpace(slc[4])
+			obj.retainUntilDate = strings.TrimSpace(slc[5])
+			obj.legalHold = strings.TrimSpace(slc[6])
+		}
 		copyState.queueUploadTask(obj)
 		logDMsg(fmt.Sprintf("adding %s to copy queue", o), nil)
 	}
@@ -0,0 +1,187 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	miniogo "github.com/minio/minio-go/v7"
+)
+
+// objInfo describes a single object version queued for replay from the
+// source bucket to the target bucket.
+type objInfo struct {
+	bucket       string
+	object       string
+	versionID    string
+	deleteMarker bool
+
+	// retention/legal hold state read from the input file, populated only
+	// when --preserve-locks is set.
+	retentionMode   string
+	retainUntilDate string
+	legalHold       string
+}
+
+const copyQueueSize = 10000
+const copyWorkerCount = 100
+
+// copyst tracks the in-flight and completed state of a copy run. It is
+// referred to throughout the codebase via the package-level copyState
+// variable.
+type copyst struct {
+	wg    sync.WaitGroup
+	tasks chan objInfo
+
+	count     uint64
+	failCount uint64
+}
+
+func newcopyState(ctx context.Context) *copyst {
+	return &copyst{
+		tasks: make(chan objInfo, copyQueueSize),
+	}
+}
+
+// init starts the worker pool that drains queued tasks and replays them
+// against the target bucket.
+func (c *copyst) init(ctx context.Context) {
+	for i := 0; i < copyWorkerCount; i++ {
+		c.wg.Add(1)
+		go c.worker(ctx)
+	}
+}
+
+func (c *copyst) queueUploadTask(o objInfo) {
+	c.tasks <- o
+}
+
+// finish closes the task channel and waits for all workers to drain it.
+func (c *copyst) finish(ctx context.Context) {
+	close(c.tasks)
+	c.wg.Wait()
+}
+
+func (c *copyst) getCount() uint64 {
+	return atomic.LoadUint64(&c.count)
+}
+
+func (c *copyst) getFailCount() uint64 {
+	return atomic.LoadUint64(&c.failCount)
+}
+
+func (c *copyst) worker(ctx context.Context) {
+	defer c.wg.Done()
+	for o := range c.tasks {
+		atomic.AddUint64(&c.count, 1)
+		if err := c.copyObject(ctx, o); err != nil {
+			atomic.AddUint64(&c.failCount, 1)
+			logDMsg(fmt.Sprintf("failed to copy %s/%s (v:%s)", o.bucket, o.object, o.versionID), err)
+		}
+	}
+}
+
+// copyObject replays a single queued object version against the target
+// bucket, either as a versioned delete marker or a full object copy.
+func (c *copyst) copyObject(ctx context.Context, o objInfo) error {
+	if dryRun {
+		logDMsg(fmt.Sprintf("[dry run] would copy %s/%s (v:%s)", o.bucket, o.object, o.versionID), nil)
+		return nil
+	}
+	if o.deleteMarker {
+		return tgtClient.RemoveObject(ctx, tgtBucket, o.object, miniogo.RemoveObjectOptions{
+			VersionID: o.versionID,
+		})
+	}
+
+	var meta *srcObjectMeta
+	if preserveMetadata {
+		var err error
+		meta, err = statSourceObject(ctx, o)
+		if err != nil {
+			return fmt.Errorf("could not read source metadata for %s/%s: %w", o.bucket, o.object, err)
+		}
+		if tgtInfo, err := tgtClient.StatObject(ctx, tgtBucket, o.object, miniogo.StatObjectOptions{VersionID: o.versionID}); err == nil {
+			if metadataDrifted(tgtInfo, meta) {
+				logMsg(fmt.Sprintf("metadata drift detected on %s/%s (v:%s): target metadata does not match source", o.bucket, o.object, o.versionID))
+			}
+			if metadataOnly && tgtInfo.ETag == meta.info.ETag {
+				return c.resyncMetadata(ctx, o, meta)
+			}
+		}
+	}
+
+	if serverSideCopy && !reencryptionRequired() {
+		ok, err := c.serverSideCopyObject(ctx, o, meta)
+		if err != nil {
+			return fmt.Errorf("server-side copy failed for %s/%s: %w", o.bucket, o.object, err)
+		}
+		if ok {
+			if preserveLocks {
+				if err := c.replayObjectLock(ctx, o); err != nil {
+					return fmt.Errorf("could not replay object lock for %s/%s: %w", o.bucket, o.object, err)
+				}
+			}
+			return nil
+		}
+		// fall through to the streaming PUT path below.
+	}
+
+	opts := miniogo.GetObjectOptions{}
+	if o.versionID != "" {
+		opts.VersionID = o.versionID
+	}
+	if srcSSEC != nil {
+		opts.ServerSideEncryption = srcSSEC
+	}
+	reader, err := srcClient.GetObject(ctx, o.bucket, o.object, opts)
+	if err != nil {
+		return fmt.Errorf("could not get object %s/%s: %w", o.bucket, o.object, err)
+	}
+	defer reader.Close()
+
+	stat, err := reader.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat object %s/%s: %w", o.bucket, o.object, err)
+	}
+
+	putOpts := miniogo.PutObjectOptions{ContentType: stat.ContentType}
+	if preserveMetadata {
+		putOpts, err = putOptionsFromMeta(meta)
+		if err != nil {
+			return fmt.Errorf("could not build put options for %s/%s: %w", o.bucket, o.object, err)
+		}
+	}
+	if sse := tgtServerSide(); sse != nil {
+		putOpts.ServerSideEncryption = sse
+	}
+
+	_, err = tgtClient.PutObject(ctx, tgtBucket, o.object, reader, stat.Size, putOpts)
+	if err != nil {
+		return fmt.Errorf("could not put object %s/%s: %w", o.bucket, o.object, err)
+	}
+
+	if preserveLocks {
+		if err := c.replayObjectLock(ctx, o); err != nil {
+			return fmt.Errorf("could not replay object lock for %s/%s: %w", o.bucket, o.object, err)
+		}
+	}
+	return nil
+}
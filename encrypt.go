@@ -0,0 +1,105 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+var (
+	srcSSEC   encrypt.ServerSide
+	tgtSSEC   encrypt.ServerSide
+	tgtSSEKMS encrypt.ServerSide
+)
+
+// encryptFlagsFromCLI builds the source/target server-side-encryption
+// contexts from the --src-sse-c-key[-file]/--tgt-sse-c-key/
+// --tgt-sse-kms-key-id flags. It is called once from checkCopyArgsAndInit.
+func encryptFlagsFromCLI(srcSSECKey, srcSSECKeyFile, tgtSSECKey, tgtKMSKeyID string) error {
+	key := srcSSECKey
+	if srcSSECKeyFile != "" {
+		b, err := os.ReadFile(srcSSECKeyFile)
+		if err != nil {
+			return fmt.Errorf("could not read --src-sse-c-key-file: %w", err)
+		}
+		key = strings.TrimSpace(string(b))
+	}
+	if key != "" {
+		sse, err := encrypt.NewSSEC([]byte(key))
+		if err != nil {
+			return fmt.Errorf("invalid --src-sse-c-key: %w", err)
+		}
+		srcSSEC = sse
+	}
+
+	if tgtSSECKey != "" {
+		sse, err := encrypt.NewSSEC([]byte(tgtSSECKey))
+		if err != nil {
+			return fmt.Errorf("invalid --tgt-sse-c-key: %w", err)
+		}
+		tgtSSEC = sse
+	}
+
+	if tgtKMSKeyID != "" {
+		sse, err := encrypt.NewSSEKMS(tgtKMSKeyID, nil)
+		if err != nil {
+			return fmt.Errorf("invalid --tgt-sse-kms-key-id: %w", err)
+		}
+		tgtSSEKMS = sse
+	}
+	return nil
+}
+
+// tgtServerSide returns the encryption context to put objects with: SSE-C
+// if a target customer key was supplied, else SSE-KMS if a key ID was
+// supplied, else nil (no explicit per-object encryption).
+func tgtServerSide() encrypt.ServerSide {
+	if tgtSSEC != nil {
+		return tgtSSEC
+	}
+	return tgtSSEKMS
+}
+
+// reencryptionRequired reports whether moving an object from its source
+// encryption to its target encryption can't be done server-side and must
+// instead be re-encrypted via a streaming PUT. Only an SSE-C source with
+// an SSE-KMS target key is detected; there is no flag for a source SSE-KMS
+// key, so an SSE-KMS source with an SSE-C target is not detected.
+func reencryptionRequired() bool {
+	return srcSSEC != nil && tgtSSEKMS != nil
+}
+
+// logBucketEncryption fetches the bucket-default encryption configuration
+// for bucket on client and logs the effective per-object encryption
+// headers an operator should expect objects to land with, when no
+// per-object SSE-C/SSE-KMS flag overrides it.
+func logBucketEncryption(ctx context.Context, client *miniogo.Client, bucket string) {
+	cfg, err := client.GetBucketEncryption(ctx, bucket)
+	if err != nil {
+		logDMsg(fmt.Sprintf("no bucket-default encryption configured on %s", bucket), nil)
+		return
+	}
+	for _, rule := range cfg.Rules {
+		logMsg(fmt.Sprintf("bucket %s default encryption: %s (key: %s)", bucket, rule.Apply.SSEAlgorithm, rule.Apply.KmsMasterKeyID))
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+)
+
+// preserveLocks is set by --preserve-locks and enables reading object lock
+// retention/legal hold from the source and replaying it on the target.
+var preserveLocks bool
+
+var (
+	tgtLockEnabledOnce sync.Once
+	tgtLockEnabled     bool
+)
+
+// targetHasObjectLock reports whether the target bucket was created with
+// object-lock enabled. The result is cached for the duration of the run
+// since bucket configuration does not change mid-copy.
+func targetHasObjectLock(ctx context.Context) bool {
+	tgtLockEnabledOnce.Do(func() {
+		_, _, _, _, err := tgtClient.GetObjectLockConfig(ctx, tgtBucket)
+		tgtLockEnabled = err == nil
+	})
+	return tgtLockEnabled
+}
+
+// replayObjectLock fetches the retention mode/RetainUntilDate and legal hold
+// status for a source object version and re-applies them on the
+// corresponding target object version. It is a no-op, logged as a warning,
+// when the target bucket has no object-lock configuration.
+func (c *copyst) replayObjectLock(ctx context.Context, o objInfo) error {
+	if !targetHasObjectLock(ctx) {
+		logMsg(fmt.Sprintf("warning: target bucket %s has no object-lock configuration, skipping lock replay for %s/%s", tgtBucket, o.bucket, o.object))
+		return nil
+	}
+
+	mode, retainUntil, err := parseRetention(o)
+	if err != nil {
+		return err
+	}
+	if mode != nil && retainUntil != nil {
+		if err := tgtClient.PutObjectRetention(ctx, tgtBucket, o.object, miniogo.PutObjectRetentionOptions{
+			VersionID:       o.versionID,
+			RetainUntilDate: retainUntil,
+			Mode:            mode,
+		}); err != nil {
+			return fmt.Errorf("PutObjectRetention failed: %w", err)
+		}
+	}
+
+	status, err := parseLegalHold(ctx, o)
+	if err != nil {
+		return err
+	}
+	if status != nil {
+		if err := tgtClient.PutObjectLegalHold(ctx, tgtBucket, o.object, miniogo.PutObjectLegalHoldOptions{
+			VersionID: o.versionID,
+			Status:    status,
+		}); err != nil {
+			return fmt.Errorf("PutObjectLegalHold failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseRetention returns the retention mode/RetainUntilDate to replay for
+// o, sourced from the input file when present, falling back to a live
+// GetObjectRetention call against srcClient otherwise.
+func parseRetention(o objInfo) (*miniogo.RetentionMode, *time.Time, error) {
+	if o.retentionMode == "" {
+		mode, retainUntil, err := srcClient.GetObjectRetention(context.Background(), o.bucket, o.object, o.versionID)
+		if err != nil {
+			// Source object may simply have no retention set.
+			return nil, nil, nil
+		}
+		return mode, retainUntil, nil
+	}
+
+	mode := miniogo.RetentionMode(o.retentionMode)
+	t, err := time.Parse(time.RFC3339, o.retainUntilDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid retain_until %q: %w", o.retainUntilDate, err)
+	}
+	return &mode, &t, nil
+}
+
+// parseLegalHold returns the legal hold status to replay for o, sourced
+// from the input file when present, falling back to a live
+// GetObjectLegalHold call against srcClient otherwise.
+func parseLegalHold(ctx context.Context, o objInfo) (*miniogo.LegalHoldStatus, error) {
+	if o.legalHold == "" {
+		status, err := srcClient.GetObjectLegalHold(ctx, o.bucket, o.object, miniogo.GetObjectLegalHoldOptions{VersionID: o.versionID})
+		if err != nil {
+			// Source object may simply have no legal hold set.
+			return nil, nil
+		}
+		return status, nil
+	}
+
+	status := miniogo.LegalHoldStatus(o.legalHold)
+	return &status, nil
+}
@@ -0,0 +1,46 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "testing"
+
+func TestParseRetentionFromInputFile(t *testing.T) {
+	o := objInfo{
+		retentionMode:   "COMPLIANCE",
+		retainUntilDate: "2030-01-01T00:00:00Z",
+	}
+	mode, retainUntil, err := parseRetention(o)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode == nil || string(*mode) != "COMPLIANCE" {
+		t.Fatalf("expected mode COMPLIANCE, got %v", mode)
+	}
+	if retainUntil == nil || retainUntil.Year() != 2030 {
+		t.Fatalf("expected retain until in 2030, got %v", retainUntil)
+	}
+}
+
+func TestParseRetentionInvalidDate(t *testing.T) {
+	o := objInfo{
+		retentionMode:   "GOVERNANCE",
+		retainUntilDate: "not-a-date",
+	}
+	if _, _, err := parseRetention(o); err == nil {
+		t.Fatal("expected error for invalid retain_until, got nil")
+	}
+}
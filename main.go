@@ -0,0 +1,119 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/console"
+)
+
+const objListFile = "object_listing.txt"
+
+var (
+	debug    bool
+	versions bool
+	dryRun   bool
+	dirPath  string
+
+	srcEndpoint  string
+	srcAccessKey string
+	srcSecretKey string
+	srcBucket    string
+
+	tgtEndpoint  string
+	tgtAccessKey string
+	tgtSecretKey string
+	tgtBucket    string
+
+	copyState *copyst
+)
+
+var allFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "S3 endpoint url for target",
+	},
+	cli.StringFlag{
+		Name:  "access-key",
+		Usage: "S3 access key for target",
+	},
+	cli.StringFlag{
+		Name:  "secret-key",
+		Usage: "S3 secret key for target",
+	},
+	cli.StringFlag{
+		Name:  "bucket",
+		Usage: "S3 bucket for target",
+	},
+	cli.StringFlag{
+		Name:  "data-dir",
+		Usage: "path to working directory containing object_listing.txt",
+	},
+	cli.BoolFlag{
+		Name:  "versions",
+		Usage: "operate on all object versions",
+	},
+	cli.BoolFlag{
+		Name:  "insecure",
+		Usage: "disable TLS certificate verification",
+	},
+	cli.BoolFlag{
+		Name:  "debug",
+		Usage: "print debug logs",
+	},
+}
+
+func mustGetSystemCertPool() *x509.CertPool {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		return x509.NewCertPool()
+	}
+	return pool
+}
+
+func logMsg(msg string) {
+	console.Println(msg)
+}
+
+func logDMsg(msg string, err error) {
+	if !debug {
+		return
+	}
+	if err != nil {
+		console.Println(fmt.Sprintf("%s: %v", msg, err))
+		return
+	}
+	console.Println(msg)
+}
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "drrepltool"
+	app.Usage = "replay or replicate object versions between MinIO/S3 endpoints for DR"
+	app.Commands = []cli.Command{
+		copyCmd,
+		syncCmd,
+		replicateSetupCmd,
+	}
+	if err := app.Run(os.Args); err != nil {
+		console.Fatalln(err)
+	}
+}
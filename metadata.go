@@ -0,0 +1,162 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+)
+
+var (
+	// preserveMetadata is on by default: re-apply the source object's
+	// user metadata, tags, ACL and storage class on the target.
+	preserveMetadata bool
+	// metadataOnly, when set, re-syncs metadata for objects whose bytes
+	// already match (by ETag) on the target without re-uploading them.
+	metadataOnly bool
+)
+
+// srcObjectMeta is everything read off the source object that needs to be
+// carried over to the target alongside the bytes themselves.
+type srcObjectMeta struct {
+	info miniogo.ObjectInfo
+	tags map[string]string
+	acl  string
+}
+
+// statSourceObject gathers the metadata, tags and ACL for a source object
+// version ahead of the copy, so a PutObjectOptions can be built for the
+// target that matches the source as closely as the target API allows.
+func statSourceObject(ctx context.Context, o objInfo) (*srcObjectMeta, error) {
+	opts := miniogo.StatObjectOptions{}
+	if o.versionID != "" {
+		opts.VersionID = o.versionID
+	}
+	if srcSSEC != nil {
+		opts.ServerSideEncryption = srcSSEC
+	}
+	info, err := srcClient.StatObject(ctx, o.bucket, o.object, opts)
+	if err != nil {
+		return nil, fmt.Errorf("StatObject failed: %w", err)
+	}
+
+	meta := &srcObjectMeta{info: info}
+
+	objTags, err := srcClient.GetObjectTagging(ctx, o.bucket, o.object, miniogo.GetObjectTaggingOptions{VersionID: o.versionID})
+	if err == nil {
+		meta.tags = objTags.ToMap()
+	}
+
+	acl, err := srcClient.GetObjectACL(ctx, o.bucket, o.object)
+	if err == nil && acl != nil {
+		meta.acl = acl.Metadata.Get("X-Amz-Acl")
+	}
+
+	return meta, nil
+}
+
+// putOptionsFromMeta rebuilds PutObjectOptions on the target so that user
+// metadata, tags, content headers and storage class survive the copy.
+func putOptionsFromMeta(meta *srcObjectMeta) (miniogo.PutObjectOptions, error) {
+	opts := miniogo.PutObjectOptions{
+		ContentType:        meta.info.ContentType,
+		ContentEncoding:    meta.info.Metadata.Get("Content-Encoding"),
+		ContentDisposition: meta.info.Metadata.Get("Content-Disposition"),
+		CacheControl:       meta.info.Metadata.Get("Cache-Control"),
+		StorageClass:       meta.info.StorageClass,
+		UserMetadata:       map[string]string{},
+	}
+	for k, v := range meta.info.UserMetadata {
+		opts.UserMetadata[k] = v
+	}
+	if len(meta.tags) > 0 {
+		t, err := tags.MapToObjectTags(meta.tags)
+		if err != nil {
+			return opts, fmt.Errorf("invalid source tags: %w", err)
+		}
+		opts.UserTags = t.ToMap()
+	}
+	if meta.acl != "" {
+		// minio-go has no first-class canned-ACL option, so the canned ACL
+		// is carried as the x-amz-acl header the same way mc does it.
+		opts.UserMetadata["X-Amz-Acl"] = meta.acl
+	}
+	return opts, nil
+}
+
+// resyncMetadata re-applies the source object's metadata, tags and ACL to
+// an already-uploaded target version without re-uploading its bytes. It is
+// only safe to call once the caller has confirmed the target ETag matches
+// the source.
+func (c *copyst) resyncMetadata(ctx context.Context, o objInfo, meta *srcObjectMeta) error {
+	userMetadata := make(map[string]string, len(meta.info.UserMetadata)+1)
+	for k, v := range meta.info.UserMetadata {
+		userMetadata[k] = v
+	}
+	dst := miniogo.CopyDestOptions{
+		Bucket:          tgtBucket,
+		Object:          o.object,
+		ReplaceMetadata: true,
+		ReplaceTags:     true,
+		UserMetadata:    userMetadata,
+	}
+	if len(meta.tags) > 0 {
+		t, err := tags.MapToObjectTags(meta.tags)
+		if err != nil {
+			return fmt.Errorf("invalid source tags: %w", err)
+		}
+		dst.UserTags = t.ToMap()
+	}
+	if meta.acl != "" {
+		// minio-go has no first-class canned-ACL option, so the canned ACL
+		// is carried as the x-amz-acl header the same way mc does it.
+		userMetadata["X-Amz-Acl"] = meta.acl
+	}
+	src := miniogo.CopySrcOptions{
+		Bucket:    tgtBucket,
+		Object:    o.object,
+		VersionID: o.versionID,
+	}
+	_, err := tgtClient.CopyObject(ctx, dst, src)
+	if err != nil {
+		return fmt.Errorf("metadata-only resync failed for %s/%s: %w", o.bucket, o.object, err)
+	}
+	logDMsg(fmt.Sprintf("resynced metadata for %s/%s without re-uploading bytes", o.bucket, o.object), nil)
+	return nil
+}
+
+// metadataDrifted reports whether the target's metadata no longer matches
+// the source, used to decide whether to log a drift warning. It compares
+// content type and user metadata only; the --metadata-only resync is
+// decided by the ETag match alone and does not consult it.
+func metadataDrifted(tgtInfo miniogo.ObjectInfo, meta *srcObjectMeta) bool {
+	if tgtInfo.ContentType != meta.info.ContentType {
+		return true
+	}
+	if len(tgtInfo.UserMetadata) != len(meta.info.UserMetadata) {
+		return true
+	}
+	for k, v := range meta.info.UserMetadata {
+		if tgtInfo.UserMetadata[k] != v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	miniogo "github.com/minio/minio-go/v7"
+)
+
+func TestMetadataDriftedContentType(t *testing.T) {
+	meta := &srcObjectMeta{info: miniogo.ObjectInfo{ContentType: "text/plain"}}
+	tgt := miniogo.ObjectInfo{ContentType: "application/octet-stream"}
+	if !metadataDrifted(tgt, meta) {
+		t.Fatal("expected drift on differing content type")
+	}
+}
+
+func TestMetadataDriftedUserMetadata(t *testing.T) {
+	meta := &srcObjectMeta{info: miniogo.ObjectInfo{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"x-amz-meta-a": "1"},
+	}}
+	tgt := miniogo.ObjectInfo{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"x-amz-meta-a": "2"},
+	}
+	if !metadataDrifted(tgt, meta) {
+		t.Fatal("expected drift on differing user metadata value")
+	}
+}
+
+func TestMetadataNotDrifted(t *testing.T) {
+	meta := &srcObjectMeta{info: miniogo.ObjectInfo{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"x-amz-meta-a": "1"},
+	}}
+	tgt := miniogo.ObjectInfo{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"x-amz-meta-a": "1"},
+	}
+	if metadataDrifted(tgt, meta) {
+		t.Fatal("expected no drift when content type and user metadata match")
+	}
+}
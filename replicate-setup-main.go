@@ -0,0 +1,204 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/madmin-go"
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/replication"
+)
+
+var replicateSetupFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "prefix",
+		Usage: "only replicate keys matching this prefix",
+	},
+	cli.BoolFlag{
+		Name:  "existing-object-replication",
+		Usage: "trigger replication of object versions already listed in object_listing.txt",
+	},
+}
+
+var replicateSetupCmd = cli.Command{
+	Name:   "replicate-setup",
+	Usage:  "provision native MinIO bucket replication from source to target instead of client-side copying",
+	Action: replicateSetupAction,
+	Flags:  append(append(allFlags, srcFlags...), replicateSetupFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}}  --dir
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+
+EXAMPLES:
+1. Provision bucket replication from srcbucket at https://minio1 to dstbucket at https://minio2
+   $ drrepltool replicate-setup --data-dir "/tmp/data" --endpoint https://minio2 --access-key minio --secret-key minio123 --bucket "dstbucket" \
+     --src-endpoint https://minio1 --src-access-key minio1 --src-secret-key minio123 --src-bucket srcbucket
+`,
+}
+
+func replicateSetupAction(cliCtx *cli.Context) error {
+	checkCopyArgsAndInit(cliCtx)
+	srcClient, err = initMinioClient(cliCtx, srcAccessKey, srcSecretKey, srcBucket, srcEndpoint)
+	if err != nil {
+		return fmt.Errorf("could not initialize src client %w", err)
+	}
+	tgtClient, err = initMinioClient(cliCtx, tgtAccessKey, tgtSecretKey, tgtBucket, tgtEndpoint)
+	if err != nil {
+		return fmt.Errorf("could not initialize tgt client %w", err)
+	}
+
+	ctx := context.Background()
+	if err := ensureVersioned(ctx, srcClient, srcBucket); err != nil {
+		return err
+	}
+	if err := ensureVersioned(ctx, tgtClient, tgtBucket); err != nil {
+		return err
+	}
+
+	arn, err := addRemoteTarget(ctx, cliCtx)
+	if err != nil {
+		return fmt.Errorf("could not add remote target: %w", err)
+	}
+	logMsg(fmt.Sprintf("added remote target %s on %s", arn, srcEndpoint))
+
+	prefix := cliCtx.String("prefix")
+	cfg, err := replicationConfig(arn, prefix)
+	if err != nil {
+		return fmt.Errorf("could not build replication config: %w", err)
+	}
+	if err := srcClient.SetBucketReplication(ctx, srcBucket, cfg); err != nil {
+		return fmt.Errorf("could not set bucket replication: %w", err)
+	}
+	logMsg(fmt.Sprintf("replication rule installed on %s -> %s", srcBucket, tgtBucket))
+
+	if cliCtx.Bool("existing-object-replication") {
+		if err := healExistingObjects(ctx); err != nil {
+			return fmt.Errorf("could not trigger replication of existing objects: %w", err)
+		}
+	}
+	return nil
+}
+
+// ensureVersioned turns on bucket versioning when it isn't already enabled;
+// native replication cannot be configured on an unversioned bucket.
+func ensureVersioned(ctx context.Context, client *miniogo.Client, bucket string) error {
+	cfg, err := client.GetBucketVersioning(ctx, bucket)
+	if err == nil && cfg.Status == "Enabled" {
+		return nil
+	}
+	if err := client.EnableVersioning(ctx, bucket); err != nil {
+		return fmt.Errorf("could not enable versioning on %s: %w", bucket, err)
+	}
+	logMsg(fmt.Sprintf("enabled versioning on %s", bucket))
+	return nil
+}
+
+// addRemoteTarget registers the target bucket as a remote replication
+// target on the source, reusing the same credentials already validated by
+// checkCopyArgsAndInit, and returns the ARN admin-config remote-target add
+// produces.
+func addRemoteTarget(ctx context.Context, cliCtx *cli.Context) (string, error) {
+	target := madmin.BucketTarget{
+		SourceBucket: srcBucket,
+		TargetBucket: tgtBucket,
+		Endpoint:     strings.TrimPrefix(strings.TrimPrefix(tgtEndpoint, "https://"), "http://"),
+		Credentials: &madmin.Credentials{
+			AccessKey: tgtAccessKey,
+			SecretKey: tgtSecretKey,
+		},
+		Secure: strings.HasPrefix(tgtEndpoint, "https://"),
+		Type:   madmin.ReplicationService,
+	}
+	adminClient, err := madmin.New(strings.TrimPrefix(strings.TrimPrefix(srcEndpoint, "https://"), "http://"), srcAccessKey, srcSecretKey, strings.HasPrefix(srcEndpoint, "https://"))
+	if err != nil {
+		return "", err
+	}
+	return adminClient.SetRemoteTarget(ctx, srcBucket, &target)
+}
+
+// replicationConfig builds a single-rule replication configuration that
+// covers prefix (or the whole bucket when prefix is empty) and targets the
+// remote bucket via arn, the same way `mc replicate add` does.
+func replicationConfig(arn, prefix string) (replication.Config, error) {
+	cfg := replication.Config{}
+	err := cfg.AddRule(replication.Options{
+		Op:                     replication.AddOption,
+		RoleArn:                arn,
+		ID:                     "drrepltool-migration",
+		Prefix:                 prefix,
+		RuleStatus:             "enable",
+		Priority:               "1",
+		DestBucket:             tgtBucket,
+		ReplicateDeletes:       "enable",
+		ReplicateDeleteMarkers: "enable",
+	})
+	return cfg, err
+}
+
+// healExistingObjects issues a self CopyObject, one per line, for every
+// object version already listed in object_listing.txt. ReplaceMetadata
+// forces the server to treat it as a metadata update, which is what
+// queues an already-existing version for replication the same way a
+// x-minio-force-metadata-update PUT would.
+func healExistingObjects(ctx context.Context) error {
+	file, err := os.Open(path.Join(dirPath, objListFile))
+	if err != nil {
+		return fmt.Errorf("--input-file needs to be specified: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		slc := strings.SplitN(scanner.Text(), ",", 4)
+		if len(slc) < 3 {
+			continue
+		}
+		bucket := strings.TrimSpace(slc[0])
+		object := strings.TrimSpace(slc[1])
+		versionID := strings.TrimSpace(slc[2])
+
+		dst := miniogo.CopyDestOptions{
+			Bucket:          bucket,
+			Object:          object,
+			ReplaceMetadata: true,
+		}
+		src := miniogo.CopySrcOptions{
+			Bucket:    bucket,
+			Object:    object,
+			VersionID: versionID,
+		}
+		if _, err := srcClient.CopyObject(ctx, dst, src); err != nil {
+			logDMsg(fmt.Sprintf("could not heal %s/%s (v:%s) for replication", bucket, object, versionID), err)
+			continue
+		}
+		logDMsg(fmt.Sprintf("queued existing version %s/%s (v:%s) for replication", bucket, object, versionID), nil)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,143 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	miniogo "github.com/minio/minio-go/v7"
+)
+
+// serverSideCopy is set by --server-side-copy and routes the copy worker
+// through CopyObject/ComposeObject instead of streaming bytes through the
+// tool, falling back to a streaming PUT when the target rejects the copy.
+//
+// minio-go's CopySrcOptions only addresses a bucket/object on the client
+// it's issued against, so there is no way to hand tgtClient a copy source
+// that lives on a different account/endpoint than tgtClient itself. The
+// fast path is therefore only attempted when src and tgt are the same
+// endpoint; cross-endpoint copies always stream through the tool.
+var serverSideCopy bool
+
+const (
+	// copyObjectMaxSize is the largest object a single CopyObject call can
+	// handle; anything bigger must be composed from part-range copies.
+	copyObjectMaxSize = 5 << 30 // 5 GiB
+
+	composePartMinSize  = 5 << 20   // 5 MiB
+	composePartPrefSize = 64 << 20  // 64 MiB
+	composePartMaxCount = 10000
+)
+
+// sameEndpoint reports whether src and tgt point at the same S3 endpoint,
+// the only configuration CopyObject/ComposeObject can actually service
+// since the copy source is resolved against tgtClient's own account.
+func sameEndpoint() bool {
+	return srcEndpoint == tgtEndpoint
+}
+
+// serverSideCopyObject attempts to replay o onto the target using
+// CopyObject (or ComposeObject for objects over copyObjectMaxSize) without
+// streaming the payload through the tool. It returns ok=false when the
+// fast path could not be used so the caller can fall back to a streaming
+// PUT, e.g. when src and tgt are different endpoints or the target
+// otherwise rejects the copy.
+func (c *copyst) serverSideCopyObject(ctx context.Context, o objInfo, meta *srcObjectMeta) (ok bool, err error) {
+	if !sameEndpoint() {
+		logDMsg(fmt.Sprintf("src and tgt are different endpoints, server-side copy not available for %s/%s", o.bucket, o.object), nil)
+		return false, nil
+	}
+
+	src := miniogo.CopySrcOptions{
+		Bucket:    o.bucket,
+		Object:    o.object,
+		VersionID: o.versionID,
+	}
+	if srcSSEC != nil {
+		src.Encryption = srcSSEC
+	}
+
+	dst := miniogo.CopyDestOptions{
+		Bucket: tgtBucket,
+		Object: o.object,
+	}
+	if meta != nil {
+		dst.UserMetadata = meta.info.UserMetadata
+		dst.ReplaceMetadata = true
+	}
+	if sse := tgtServerSide(); sse != nil {
+		dst.Encryption = sse
+	}
+
+	var size int64
+	if meta != nil {
+		size = meta.info.Size
+	} else {
+		statOpts := miniogo.StatObjectOptions{VersionID: o.versionID}
+		if srcSSEC != nil {
+			statOpts.ServerSideEncryption = srcSSEC
+		}
+		stat, serr := srcClient.StatObject(ctx, o.bucket, o.object, statOpts)
+		if serr != nil {
+			return false, fmt.Errorf("could not stat source object %s/%s: %w", o.bucket, o.object, serr)
+		}
+		size = stat.Size
+	}
+
+	if size <= copyObjectMaxSize {
+		if _, err := tgtClient.CopyObject(ctx, dst, src); err != nil {
+			logDMsg(fmt.Sprintf("server-side copy rejected for %s/%s, falling back to streaming PUT", o.bucket, o.object), err)
+			return false, nil
+		}
+		return true, nil
+	}
+
+	sources := partRangeSources(src, size)
+	if _, err := tgtClient.ComposeObject(ctx, dst, sources...); err != nil {
+		logDMsg(fmt.Sprintf("server-side compose rejected for %s/%s, falling back to streaming PUT", o.bucket, o.object), err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// partRangeSources splits size bytes into CopySrcOptions part ranges using
+// the same sizing rule as the compose-object helper: prefer 64 MiB parts,
+// never smaller than the 5 MiB minimum, never more than 10000 parts.
+func partRangeSources(src miniogo.CopySrcOptions, size int64) []miniogo.CopySrcOptions {
+	partSize := int64(composePartPrefSize)
+	if (size+partSize-1)/partSize > composePartMaxCount {
+		partSize = (size + composePartMaxCount - 1) / composePartMaxCount
+		if partSize < composePartMinSize {
+			partSize = composePartMinSize
+		}
+	}
+
+	var sources []miniogo.CopySrcOptions
+	for start := int64(0); start < size; start += partSize {
+		end := start + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		part := src
+		part.MatchRange = true
+		part.Start = start
+		part.End = end
+		sources = append(sources, part)
+	}
+	return sources
+}
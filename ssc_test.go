@@ -0,0 +1,60 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	miniogo "github.com/minio/minio-go/v7"
+)
+
+func TestPartRangeSourcesPrefersSixtyFourMiB(t *testing.T) {
+	size := int64(200 << 20) // 200 MiB
+	parts := partRangeSources(miniogo.CopySrcOptions{}, size)
+
+	var total int64
+	for i, p := range parts {
+		if p.End-p.Start+1 < composePartMinSize && i != len(parts)-1 {
+			t.Fatalf("part %d smaller than minimum part size: %d", i, p.End-p.Start+1)
+		}
+		if i != len(parts)-1 && p.End-p.Start+1 != composePartPrefSize {
+			t.Fatalf("part %d is %d bytes, expected the preferred %d", i, p.End-p.Start+1, composePartPrefSize)
+		}
+		total += p.End - p.Start + 1
+	}
+	if total != size {
+		t.Fatalf("parts do not cover the full object: got %d want %d", total, size)
+	}
+}
+
+func TestPartRangeSourcesCapsPartCount(t *testing.T) {
+	// Large enough that 64 MiB parts would exceed the 10000 part cap.
+	size := int64(composePartMaxCount+1) * composePartPrefSize
+	parts := partRangeSources(miniogo.CopySrcOptions{}, size)
+
+	if len(parts) > composePartMaxCount {
+		t.Fatalf("expected at most %d parts, got %d", composePartMaxCount, len(parts))
+	}
+
+	var total int64
+	for _, p := range parts {
+		total += p.End - p.Start + 1
+	}
+	if total != size {
+		t.Fatalf("parts do not cover the full object: got %d want %d", total, size)
+	}
+}
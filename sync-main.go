@@ -0,0 +1,205 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/minio/cli"
+	"github.com/minio/minio-go/v7/pkg/notification"
+	"github.com/minio/minio/pkg/console"
+)
+
+var syncFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "prefix",
+		Usage: "only replicate keys matching this prefix",
+	},
+	cli.StringFlag{
+		Name:  "suffix",
+		Usage: "only replicate keys matching this suffix",
+	},
+}
+
+var syncCmd = cli.Command{
+	Name:   "sync",
+	Usage:  "continuously replicate a source bucket to a target as new events arrive, without server-side bucket replication",
+	Action: syncAction,
+	Flags:  append(append(allFlags, srcFlags...), syncFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}}  --dir
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+
+EXAMPLES:
+1. Continuously replicate srcbucket at https://minio1 to dstbucket at https://minio2
+   $ drrepltool sync --data-dir "/tmp/data" --endpoint https://minio2 --access-key minio --secret-key minio123 --bucket "dstbucket" \
+     --src-endpoint https://minio1 --src-access-key minio1 --src-secret-key minio123 --src-bucket srcbucket
+`,
+}
+
+// checkpointFile records the sequencer of the last event successfully
+// replicated for each object version, so a restarted sync resumes without
+// replaying already-applied events.
+const checkpointFile = "sync_checkpoint.txt"
+
+func syncAction(cliCtx *cli.Context) error {
+	checkCopyArgsAndInit(cliCtx)
+	srcClient, err = initMinioClient(cliCtx, srcAccessKey, srcSecretKey, srcBucket, srcEndpoint)
+	if err != nil {
+		return fmt.Errorf("could not initialize src client %w", err)
+	}
+	tgtClient, err = initMinioClient(cliCtx, tgtAccessKey, tgtSecretKey, tgtBucket, tgtEndpoint)
+	if err != nil {
+		return fmt.Errorf("could not initialize tgt client %w", err)
+	}
+
+	ctx := context.Background()
+	copyState = newcopyState(ctx)
+	copyState.init(ctx)
+
+	cp := newCheckpoint(path.Join(dirPath, checkpointFile))
+	if err := cp.load(); err != nil {
+		return fmt.Errorf("could not load checkpoint: %w", err)
+	}
+
+	prefix := cliCtx.String("prefix")
+	suffix := cliCtx.String("suffix")
+
+	events := []string{
+		"s3:ObjectCreated:*",
+		"s3:ObjectRemoved:*",
+		"s3:ObjectAccessed:*",
+	}
+
+	notifCh := srcClient.ListenBucketNotification(ctx, srcBucket, prefix, suffix, events)
+	logMsg(fmt.Sprintf("listening for bucket notifications on %s", srcBucket))
+
+	coalescer := newEventCoalescer(copyState, cp)
+	for notif := range notifCh {
+		if notif.Err != nil {
+			logDMsg("error receiving bucket notification", notif.Err)
+			continue
+		}
+		for _, rec := range notif.Records {
+			coalescer.handle(rec)
+		}
+	}
+	copyState.finish(ctx)
+	return nil
+}
+
+// checkpoint tracks, per object version, the sequencer of the last event
+// successfully replicated to the target, persisted under --data-dir so a
+// restarted sync doesn't replay events it already applied.
+type checkpoint struct {
+	mu   sync.Mutex
+	path string
+	seen map[string]string // "bucket/object/versionID" -> sequencer
+}
+
+func newCheckpoint(path string) *checkpoint {
+	return &checkpoint{path: path, seen: map[string]string{}}
+}
+
+func (c *checkpoint) load() error {
+	f, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		c.seen[parts[0]] = parts[1]
+	}
+	return scanner.Err()
+}
+
+// record persists that sequencer was the last event applied for key, and
+// reports whether the event is newer than what's already checkpointed.
+func (c *checkpoint) record(key, sequencer string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if prev, ok := c.seen[key]; ok && prev >= sequencer {
+		return false
+	}
+	c.seen[key] = sequencer
+	f, err := os.OpenFile(c.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logDMsg("could not append to checkpoint file", err)
+		return true
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s\t%s\n", key, sequencer)
+	return true
+}
+
+// eventCoalescer turns raw bucket notification records into objInfo tasks,
+// dropping events that a newer event for the same version has already
+// superseded, and translating delete-marker creation into a versioned
+// delete on the target.
+type eventCoalescer struct {
+	state *copyst
+	cp    *checkpoint
+}
+
+func newEventCoalescer(state *copyst, cp *checkpoint) *eventCoalescer {
+	return &eventCoalescer{state: state, cp: cp}
+}
+
+func (e *eventCoalescer) handle(rec notification.Event) {
+	key := fmt.Sprintf("%s/%s/%s", rec.S3.Bucket.Name, rec.S3.Object.Key, rec.S3.Object.VersionID)
+	if !e.cp.record(key, rec.S3.Object.Sequencer) {
+		logDMsg(fmt.Sprintf("skipping superseded event for %s", key), nil)
+		return
+	}
+
+	if strings.HasPrefix(rec.EventName, "s3:ObjectAccessed") {
+		// Access events carry no replication work of their own; they only
+		// move the checkpoint forward so resumed syncs don't re-scan them.
+		return
+	}
+
+	obj := objInfo{
+		bucket:    rec.S3.Bucket.Name,
+		object:    rec.S3.Object.Key,
+		versionID: rec.S3.Object.VersionID,
+	}
+	if strings.HasPrefix(rec.EventName, "s3:ObjectRemoved") {
+		obj.deleteMarker = true
+	}
+	e.state.queueUploadTask(obj)
+	console.Println(fmt.Sprintf("queued %s for %s", obj.object, rec.EventName))
+}
@@ -0,0 +1,51 @@
+/*
+ * MinIO Client (C) 2022 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointRecordSkipsStaleSequencer(t *testing.T) {
+	cp := newCheckpoint(filepath.Join(t.TempDir(), "sync_checkpoint.txt"))
+
+	if !cp.record("bucket/obj/v1", "2") {
+		t.Fatal("expected first event to be recorded")
+	}
+	if cp.record("bucket/obj/v1", "1") {
+		t.Fatal("expected older sequencer to be rejected as superseded")
+	}
+	if !cp.record("bucket/obj/v1", "3") {
+		t.Fatal("expected newer sequencer to be recorded")
+	}
+}
+
+func TestCheckpointLoadResumesFromDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync_checkpoint.txt")
+
+	cp := newCheckpoint(path)
+	cp.record("bucket/obj/v1", "5")
+
+	resumed := newCheckpoint(path)
+	if err := resumed.load(); err != nil {
+		t.Fatalf("unexpected error loading checkpoint: %v", err)
+	}
+	if resumed.record("bucket/obj/v1", "4") {
+		t.Fatal("expected sequencer already on disk to reject an older event after resume")
+	}
+}